@@ -0,0 +1,53 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscaler
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"k8s.io/client-go/tools/record"
+)
+
+// autoScalerManager turns the plans PD returns for a TidbClusterAutoScaler
+// into create/update/delete operations against the autoscaled child
+// TidbClusters. Each accepted or rejected replica/resource update of an
+// existing child TidbCluster is recorded as a Kubernetes Event on the
+// TidbClusterAutoScaler; creating or deleting a child TidbCluster, and
+// rejecting a plan for an unsupported component, records no Event.
+type autoScalerManager struct {
+	cli       versioned.Interface
+	tcLister  listers.TidbClusterLister
+	tmLister  listers.TidbMonitorLister
+	tcControl controller.TidbClusterControlInterface
+	recorder  record.EventRecorder
+}
+
+// NewAutoScalerManager returns an autoScalerManager backed by the given
+// clientset, listers, TidbCluster control interface and event recorder.
+func NewAutoScalerManager(
+	cli versioned.Interface,
+	tcLister listers.TidbClusterLister,
+	tmLister listers.TidbMonitorLister,
+	tcControl controller.TidbClusterControlInterface,
+	recorder record.EventRecorder,
+) *autoScalerManager {
+	return &autoScalerManager{
+		cli:       cli,
+		tcLister:  tcLister,
+		tmLister:  tmLister,
+		tcControl: tcControl,
+		recorder:  recorder,
+	}
+}
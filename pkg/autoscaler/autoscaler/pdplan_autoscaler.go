@@ -14,25 +14,342 @@
 package autoscaler
 
 import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// defaultScaleStepSize bounds how many replicas a single sync may add or
+	// remove for a group, so a plan spike doesn't translate into a step
+	// change that fights a rolling upgrade in progress.
+	defaultScaleStepSize int32 = 1
+
+	// scaleStepSizeAnnotationKey lets a cluster operator raise or lower the
+	// per-sync step size without a CRD schema change.
+	scaleStepSizeAnnotationKey = "tidb.pingcap.com/scale-step-size"
+
+	// lastScaleTimestampAnnotationPrefix is suffixed with the autoscaling
+	// group name to track, per group, when a sync last changed the group's
+	// replica count, so ScaleIn/ScaleOutIntervalSeconds can be enforced. An
+	// accepted resource-class change that leaves the replica count as it is
+	// does not move this timestamp.
+	lastScaleTimestampAnnotationPrefix = "tidb.pingcap.com/last-scale-timestamp-"
+
+	// resourceTypeAnnotationPrefix records, per group, the AutoResource
+	// class a child TidbCluster was last reconciled with, so a resource-class
+	// change in the plan can be detected and applied outside of the replica
+	// step limit.
+	resourceTypeAnnotationPrefix = "tidb.pingcap.com/resource-type-"
+
+	reasonScalingAccepted = "AutoScalingDecisionAccepted"
+	reasonScalingRejected = "AutoScalingDecisionRejected"
 )
 
+// scalingDecision is the diff planner's verdict for a single autoscaling
+// group, surfaced to users as a Kubernetes Event on the TidbClusterAutoScaler.
+type scalingDecision struct {
+	group           string
+	component       string
+	currentReplicas int32
+	desiredReplicas int32
+	accepted        bool
+	reason          string
+}
+
+func (am *autoScalerManager) recordScalingDecision(tac *v1alpha1.TidbClusterAutoScaler, d scalingDecision) {
+	eventType := corev1.EventTypeNormal
+	reason := reasonScalingAccepted
+	if !d.accepted {
+		eventType = corev1.EventTypeWarning
+		reason = reasonScalingRejected
+	}
+	am.recorder.Eventf(tac, eventType, reason, "group %s (%s): %d -> %d: %s",
+		d.group, d.component, d.currentReplicas, d.desiredReplicas, d.reason)
+}
+
+func scaleStepSize(tac *v1alpha1.TidbClusterAutoScaler) int32 {
+	raw, ok := tac.Annotations[scaleStepSizeAnnotationKey]
+	if !ok {
+		return defaultScaleStepSize
+	}
+	step, err := strconv.Atoi(raw)
+	if err != nil || step <= 0 {
+		return defaultScaleStepSize
+	}
+	return int32(step)
+}
+
+// stepLimitedReplicas clamps desired to at most step away from current so a
+// single sync never jumps further than the configured step size.
+func stepLimitedReplicas(current, desired, step int32) int32 {
+	if desired > current+step {
+		return current + step
+	}
+	if desired < current-step {
+		return current - step
+	}
+	return desired
+}
+
+// basicAutoScalerSpecFor returns the HPA-style min/max/interval policy tac
+// declares for component, or nil if component isn't governed by one.
+//
+// Scope note: TidbClusterAutoScalerSpec only has TiKV/TiDB policy fields
+// today, so PD and TiFlash plans have no MinReplicas/MaxReplicas or
+// ScaleIn/ScaleOutIntervalSeconds to enforce here; for those two components
+// the diff planner still applies the step size in updateAutoscalingClusters,
+// it just has no bounds/cooldown to clamp against. Giving PD/TiFlash the same
+// policy as TiKV/TiDB needs a TidbClusterAutoScalerSpec change (new PD/TiFlash
+// BasicAutoScalerSpec fields), which is out of scope for this change.
+func basicAutoScalerSpecFor(tac *v1alpha1.TidbClusterAutoScaler, component string) *v1alpha1.BasicAutoScalerSpec {
+	switch component {
+	case string(v1alpha1.TiKVMemberType):
+		if tac.Spec.TiKV != nil {
+			return &tac.Spec.TiKV.BasicAutoScalerSpec
+		}
+	case string(v1alpha1.TiDBMemberType):
+		if tac.Spec.TiDB != nil {
+			return &tac.Spec.TiDB.BasicAutoScalerSpec
+		}
+	}
+	return nil
+}
+
+func clampToBounds(basic *v1alpha1.BasicAutoScalerSpec, desired int32) int32 {
+	if basic == nil {
+		return desired
+	}
+	if basic.MinReplicas != nil && desired < *basic.MinReplicas {
+		desired = *basic.MinReplicas
+	}
+	if basic.MaxReplicas > 0 && desired > basic.MaxReplicas {
+		desired = basic.MaxReplicas
+	}
+	return desired
+}
+
+// inCooldown reports whether group is still within its scale-in/scale-out
+// cooldown window, as recorded by a previous sync's lastScaleTimestamp
+// annotation.
+func inCooldown(tac *v1alpha1.TidbClusterAutoScaler, group string, scaleOut bool, basic *v1alpha1.BasicAutoScalerSpec) bool {
+	if basic == nil {
+		return false
+	}
+	raw, ok := tac.Annotations[lastScaleTimestampAnnotationPrefix+group]
+	if !ok {
+		return false
+	}
+	last, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return false
+	}
+
+	var intervalSeconds int32
+	if scaleOut {
+		if basic.ScaleOutIntervalSeconds != nil {
+			intervalSeconds = *basic.ScaleOutIntervalSeconds
+		}
+	} else if basic.ScaleInIntervalSeconds != nil {
+		intervalSeconds = *basic.ScaleInIntervalSeconds
+	}
+	if intervalSeconds <= 0 {
+		return false
+	}
+	return time.Since(last) < time.Duration(intervalSeconds)*time.Second
+}
+
+func setLastScaleTimestamp(tac *v1alpha1.TidbClusterAutoScaler, group string, now time.Time) {
+	if tac.Annotations == nil {
+		tac.Annotations = make(map[string]string)
+	}
+	tac.Annotations[lastScaleTimestampAnnotationPrefix+group] = now.Format(time.RFC3339)
+}
+
+func currentResourceType(tac *v1alpha1.TidbClusterAutoScaler, group string) string {
+	return tac.Annotations[resourceTypeAnnotationPrefix+group]
+}
+
+func setCurrentResourceType(tac *v1alpha1.TidbClusterAutoScaler, group, resourceType string) {
+	if tac.Annotations == nil {
+		tac.Annotations = make(map[string]string)
+	}
+	tac.Annotations[resourceTypeAnnotationPrefix+group] = resourceType
+}
+
+func resourceForType(tac *v1alpha1.TidbClusterAutoScaler, resourceType string) v1alpha1.AutoResource {
+	for _, res := range tac.Spec.Resources {
+		if res.ResourceType == resourceType {
+			return res
+		}
+	}
+	return v1alpha1.AutoResource{}
+}
+
+func currentReplicas(tc *v1alpha1.TidbCluster, component string) int32 {
+	switch component {
+	case string(v1alpha1.TiKVMemberType):
+		if tc.Spec.TiKV != nil {
+			return tc.Spec.TiKV.Replicas
+		}
+	case string(v1alpha1.TiDBMemberType):
+		if tc.Spec.TiDB != nil {
+			return tc.Spec.TiDB.Replicas
+		}
+	case string(v1alpha1.PDMemberType):
+		if tc.Spec.PD != nil {
+			return tc.Spec.PD.Replicas
+		}
+	case string(v1alpha1.TiFlashMemberType):
+		if tc.Spec.TiFlash != nil {
+			return tc.Spec.TiFlash.Replicas
+		}
+	}
+	return 0
+}
+
+func setReplicasAndResource(tc *v1alpha1.TidbCluster, component string, replicas int32, resource v1alpha1.AutoResource) {
+	resList := corev1.ResourceList{
+		corev1.ResourceCPU:     resource.CPU,
+		corev1.ResourceStorage: resource.Storage,
+		corev1.ResourceMemory:  resource.Memory,
+	}
+	switch component {
+	case string(v1alpha1.TiKVMemberType):
+		tc.Spec.TiKV.Replicas = replicas
+		tc.Spec.TiKV.Limits = resList
+		tc.Spec.TiKV.Requests = resList
+	case string(v1alpha1.TiDBMemberType):
+		tc.Spec.TiDB.Replicas = replicas
+		tc.Spec.TiDB.Limits = resList
+		tc.Spec.TiDB.Requests = resList
+	case string(v1alpha1.PDMemberType):
+		tc.Spec.PD.Replicas = replicas
+		tc.Spec.PD.Limits = resList
+		tc.Spec.PD.Requests = resList
+	case string(v1alpha1.TiFlashMemberType):
+		tc.Spec.TiFlash.Replicas = replicas
+		tc.Spec.TiFlash.Limits = resList
+		tc.Spec.TiFlash.Requests = resList
+	}
+}
+
+// supportedAutoscalingComponents are the plan.Component values the plan-sync
+// pipeline knows how to translate into a child TidbCluster spec.
+var supportedAutoscalingComponents = sets.NewString(
+	string(v1alpha1.TiKVMemberType),
+	string(v1alpha1.TiDBMemberType),
+	string(v1alpha1.PDMemberType),
+	string(v1alpha1.TiFlashMemberType),
+)
+
+func validateAutoscalingComponent(component string) error {
+	if !supportedAutoscalingComponents.Has(component) {
+		return fmt.Errorf("unsupported autoscaling component %q", component)
+	}
+	return nil
+}
+
+// autoscalingChildOwnerRefs returns the owner references to stamp on a child
+// TidbCluster created for autoscaling: the TidbClusterAutoScaler is the
+// controller owner and the parent TidbCluster is a second, non-controller
+// owner. Kubernetes garbage-collects a dependent only once all of its owners
+// are gone, so a child survives deletion of the TAC alone and is collected
+// once both the TAC and the parent TidbCluster have been deleted.
+func autoscalingChildOwnerRefs(tc *v1alpha1.TidbCluster, tac *v1alpha1.TidbClusterAutoScaler) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion:         v1alpha1.SchemeGroupVersion.String(),
+			Kind:               "TidbCluster",
+			Name:               tc.Name,
+			UID:                tc.UID,
+			Controller:         pointer.BoolPtr(false),
+			BlockOwnerDeletion: pointer.BoolPtr(false),
+		},
+		{
+			APIVersion:         v1alpha1.SchemeGroupVersion.String(),
+			Kind:               "TidbClusterAutoScaler",
+			Name:               tac.Name,
+			UID:                tac.UID,
+			Controller:         pointer.BoolPtr(true),
+			BlockOwnerDeletion: pointer.BoolPtr(true),
+		},
+	}
+}
+
+// reconcileOrphanedClusters adopts autoscaled child TidbClusters that have no
+// controller owner reference at all (e.g. created by an older operator
+// version); a child already controlled by some other owner is left as it is.
+// It also garbage-collects ones whose autoscaling group is no longer
+// part of the current plan set but that fell outside the `In` selector used
+// by the rest of syncPlans, which only looks at groups in the current plan.
+func (am *autoScalerManager) reconcileOrphanedClusters(tc *v1alpha1.TidbCluster, tac *v1alpha1.TidbClusterAutoScaler, groupNames sets.String) error {
+	requirement, err := labels.NewRequirement(label.AutoScalingGroupLabelKey, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	selector := labels.NewSelector().Add(*requirement)
+
+	tcList, err := am.tcLister.List(selector)
+	if err != nil {
+		return err
+	}
+
+	for _, childTc := range tcList {
+		if childTc.Spec.Cluster == nil || childTc.Spec.Cluster.Namespace != tc.Namespace || childTc.Spec.Cluster.Name != tc.Name {
+			continue
+		}
+
+		groupName := childTc.Labels[label.AutoScalingGroupLabelKey]
+		if !groupNames.Has(groupName) {
+			err = am.deleteAutoscalingClusters(tc, []string{groupName}, map[string]*v1alpha1.TidbCluster{groupName: childTc})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if metav1.GetControllerOf(childTc) != nil {
+			continue
+		}
+		adopted := childTc.DeepCopy()
+		adopted.OwnerReferences = autoscalingChildOwnerRefs(tc, tac)
+		_, err = am.cli.PingcapV1alpha1().TidbClusters(adopted.Namespace).Update(adopted)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (am *autoScalerManager) syncPlans(tc *v1alpha1.TidbCluster, tac *v1alpha1.TidbClusterAutoScaler, plans []pdapi.Plan) error {
 	groupNames := sets.String{}
 	groupPlanMap := make(map[string]pdapi.Plan)
 	for _, plan := range plans {
+		if err := validateAutoscalingComponent(plan.Component); err != nil {
+			return err
+		}
 		groupName := findAutoscalingGroupNameInLabels(plan.Labels)
 		groupNames.Insert(groupName)
 		groupPlanMap[groupName] = plan
 	}
+	if err := am.reconcileOrphanedClusters(tc, tac, groupNames); err != nil {
+		return err
+	}
+
 	requirement, err := labels.NewRequirement(label.AutoScalingGroupLabelKey, selection.In, groupNames.List())
 	if err != nil {
 		return err
@@ -52,24 +369,38 @@ func (am *autoScalerManager) syncPlans(tc *v1alpha1.TidbCluster, tac *v1alpha1.T
 		groupTcMap[groupName] = tc
 	}
 
-	toDelete := existedGroups.Difference(groupNames)
-	err = am.deleteAutoscalingClusters(tc, toDelete.UnsortedList(), groupTcMap)
-	if err != nil {
-		return err
-	}
+	// Deletion of groups no longer in the plan is handled by
+	// reconcileOrphanedClusters above, which lists cluster-wide; tcList here is
+	// already scoped to groupNames via the `In` selector, so existedGroups can
+	// never contain a group outside groupNames and there is nothing left to
+	// delete from it.
+
+	// tacUpdate is the single copy of tac that updateAutoscalingClusters and
+	// createAutoscalingClusters accumulate their lastScaleTimestamp/resourceType
+	// annotation writes onto. It is persisted once below so a create and an
+	// update landing in the same sync can never race each other's Update call
+	// or clobber an annotation the other just wrote.
+	tacUpdate := tac.DeepCopy()
 
 	toUpdate := groupNames.Intersection(existedGroups)
-	err = am.updateAutoscalingClusters(toUpdate.UnsortedList(), groupTcMap, groupPlanMap)
+	err = am.updateAutoscalingClusters(tacUpdate, toUpdate.UnsortedList(), groupTcMap, groupPlanMap)
 	if err != nil {
 		return err
 	}
 
 	toCreate := groupNames.Difference(existedGroups)
-	err = am.createAutoscalingClusters(tc, tac, toCreate.UnsortedList(), groupPlanMap)
+	err = am.createAutoscalingClusters(tc, tacUpdate, toCreate.UnsortedList(), groupPlanMap)
 	if err != nil {
 		return err
 	}
 
+	if !reflect.DeepEqual(tacUpdate.Annotations, tac.Annotations) {
+		_, err := am.cli.PingcapV1alpha1().TidbClusterAutoScalers(tacUpdate.Namespace).Update(tacUpdate)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -77,7 +408,7 @@ func (am *autoScalerManager) deleteAutoscalingClusters(tc *v1alpha1.TidbCluster,
 	for _, group := range groupsToDelete {
 		deleteTc := groupTcMap[group]
 		err := am.cli.PingcapV1alpha1().TidbClusters(tc.Namespace).Delete(deleteTc.Name, nil)
-		if err != nil {
+		if err != nil && !apierrors.IsNotFound(err) {
 			return err
 		}
 
@@ -85,6 +416,9 @@ func (am *autoScalerManager) deleteAutoscalingClusters(tc *v1alpha1.TidbCluster,
 		if monitorRef := deleteTc.Status.Monitor; monitorRef != nil {
 			monitor, err := am.tmLister.TidbMonitors(monitorRef.Namespace).Get(monitorRef.Name)
 			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
 				return err
 			}
 			updated := monitor.DeepCopy()
@@ -96,7 +430,7 @@ func (am *autoScalerManager) deleteAutoscalingClusters(tc *v1alpha1.TidbCluster,
 			}
 			updated.Spec.Clusters = clusters
 			_, err = am.cli.PingcapV1alpha1().TidbMonitors(monitor.Namespace).Update(updated)
-			if err != nil {
+			if err != nil && !apierrors.IsNotFound(err) {
 				return err
 			}
 		}
@@ -104,27 +438,81 @@ func (am *autoScalerManager) deleteAutoscalingClusters(tc *v1alpha1.TidbCluster,
 	return nil
 }
 
-func (am *autoScalerManager) updateAutoscalingClusters(groups []string, groupTcMap map[string]*v1alpha1.TidbCluster, groupPlanMap map[string]pdapi.Plan) error {
+// updateAutoscalingClusters computes a per-group diff between the current
+// child TidbCluster replicas and what the latest plan asks for, and only
+// applies an update when the diff survives cooldown and bound checks. A
+// resource-class change is applied immediately, since it isn't a replica
+// count ramp and isn't subject to the step size or cooldown.
+//
+// tacUpdate is the caller's single shared copy of the TAC: this function only
+// mutates its annotations in memory, it is the caller's responsibility to
+// persist it (once, after both updateAutoscalingClusters and
+// createAutoscalingClusters have run) so a create and an update in the same
+// sync can't race each other's writes.
+func (am *autoScalerManager) updateAutoscalingClusters(tacUpdate *v1alpha1.TidbClusterAutoScaler, groups []string, groupTcMap map[string]*v1alpha1.TidbCluster, groupPlanMap map[string]pdapi.Plan) error {
+	step := scaleStepSize(tacUpdate)
+
 	for _, group := range groups {
 		actual, oldTc, expected := groupTcMap[group].DeepCopy(), groupTcMap[group], groupPlanMap[group]
 		component := expected.Component
+		basic := basicAutoScalerSpecFor(tacUpdate, component)
 
-		switch component {
-		case "tikv":
-			actual.Spec.TiKV.Replicas = int32(expected.Count)
-		case "tidb":
-			actual.Spec.TiDB.Replicas = int32(expected.Count)
+		current := currentReplicas(actual, component)
+		bounded := clampToBounds(basic, int32(expected.Count))
+		resourceChanged := currentResourceType(tacUpdate, group) != "" && currentResourceType(tacUpdate, group) != expected.ResourceType
+
+		if bounded == current && !resourceChanged {
+			continue
 		}
 
+		decision := scalingDecision{group: group, component: component, currentReplicas: current, desiredReplicas: bounded}
+
+		if !resourceChanged && inCooldown(tacUpdate, group, bounded > current, basic) {
+			decision.reason = "group is within its scale-in/scale-out cooldown window"
+			am.recordScalingDecision(tacUpdate, decision)
+			continue
+		}
+
+		// The replica count is always step-limited, even when the resource
+		// class is changing in the same update: a resource swap is applied
+		// immediately because it isn't a replica ramp, but it must not be
+		// used as a loophole to push an unclamped replica jump through in
+		// the same update.
+		desired := stepLimitedReplicas(current, bounded, step)
+		decision.desiredReplicas = desired
+
+		setReplicasAndResource(actual, component, desired, resourceForType(tacUpdate, expected.ResourceType))
+
 		_, err := am.tcControl.UpdateTidbCluster(actual, &actual.Status, &oldTc.Status)
 		if err != nil {
 			return err
 		}
+
+		decision.accepted = true
+		switch {
+		case resourceChanged && desired != bounded:
+			decision.reason = fmt.Sprintf("resource class changed to %s; replicas stepped from %d to %d, still ramping towards plan of %d", expected.ResourceType, current, desired, bounded)
+		case resourceChanged:
+			decision.reason = fmt.Sprintf("resource class changed to %s", expected.ResourceType)
+		default:
+			decision.reason = fmt.Sprintf("stepped replicas from %d to %d towards plan of %d", current, desired, bounded)
+		}
+		am.recordScalingDecision(tacUpdate, decision)
+
+		if desired != current {
+			setLastScaleTimestamp(tacUpdate, group, time.Now())
+		}
+		setCurrentResourceType(tacUpdate, group, expected.ResourceType)
 	}
+
 	return nil
 }
 
-func (am *autoScalerManager) createAutoscalingClusters(tc *v1alpha1.TidbCluster, tac *v1alpha1.TidbClusterAutoScaler, groupsToCreate []string, groupPlanMap map[string]pdapi.Plan) error {
+// createAutoscalingClusters creates one child TidbCluster per group in
+// groupsToCreate. tacUpdate is the caller's single shared copy of the TAC;
+// see updateAutoscalingClusters for why this function only mutates its
+// annotations rather than persisting them itself.
+func (am *autoScalerManager) createAutoscalingClusters(tc *v1alpha1.TidbCluster, tacUpdate *v1alpha1.TidbClusterAutoScaler, groupsToCreate []string, groupPlanMap map[string]pdapi.Plan) error {
 	for _, group := range groupsToCreate {
 		plan := groupPlanMap[group]
 		component := plan.Component
@@ -133,22 +521,17 @@ func (am *autoScalerManager) createAutoscalingClusters(tc *v1alpha1.TidbCluster,
 			labels[label.Key] = label.Value
 		}
 
-		var resource v1alpha1.AutoResource
-		for _, res := range tac.Spec.Resources {
-			if res.ResourceType == plan.ResourceType {
-				resource = res
-				break
-			}
-		}
+		resource := resourceForType(tacUpdate, plan.ResourceType)
 		resList := corev1.ResourceList{
 			corev1.ResourceCPU:     resource.CPU,
 			corev1.ResourceStorage: resource.Storage,
 			corev1.ResourceMemory:  resource.Memory,
 		}
-		tc := &v1alpha1.TidbCluster{
+		childTc := &v1alpha1.TidbCluster{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      group,
-				Namespace: tc.Namespace,
+				Name:            group,
+				Namespace:       tc.Namespace,
+				OwnerReferences: autoscalingChildOwnerRefs(tc, tacUpdate),
 			},
 			Spec: v1alpha1.TidbClusterSpec{
 				Cluster: &v1alpha1.TidbClusterRef{
@@ -159,8 +542,8 @@ func (am *autoScalerManager) createAutoscalingClusters(tc *v1alpha1.TidbCluster,
 		}
 
 		switch component {
-		case "tikv":
-			tc.Spec.TiKV = &v1alpha1.TiKVSpec{
+		case string(v1alpha1.TiKVMemberType):
+			childTc.Spec.TiKV = &v1alpha1.TiKVSpec{
 				Replicas: int32(plan.Count),
 				ResourceRequirements: corev1.ResourceRequirements{
 					Limits:   resList,
@@ -172,8 +555,8 @@ func (am *autoScalerManager) createAutoscalingClusters(tc *v1alpha1.TidbCluster,
 					},
 				},
 			}
-		case "tidb":
-			tc.Spec.TiDB = &v1alpha1.TiDBSpec{
+		case string(v1alpha1.TiDBMemberType):
+			childTc.Spec.TiDB = &v1alpha1.TiDBSpec{
 				Replicas: int32(plan.Count),
 				ResourceRequirements: corev1.ResourceRequirements{
 					Limits:   resList,
@@ -183,9 +566,33 @@ func (am *autoScalerManager) createAutoscalingClusters(tc *v1alpha1.TidbCluster,
 					Labels: labels,
 				},
 			}
+		case string(v1alpha1.PDMemberType):
+			childTc.Spec.PD = &v1alpha1.PDSpec{
+				Replicas: int32(plan.Count),
+				ResourceRequirements: corev1.ResourceRequirements{
+					Limits:   resList,
+					Requests: resList,
+				},
+				Config: &v1alpha1.PDConfig{
+					Schedule: &v1alpha1.PDScheduleConfig{},
+				},
+			}
+		case string(v1alpha1.TiFlashMemberType):
+			childTc.Spec.TiFlash = &v1alpha1.TiFlashSpec{
+				Replicas: int32(plan.Count),
+				ResourceRequirements: corev1.ResourceRequirements{
+					Limits:   resList,
+					Requests: resList,
+				},
+				Config: &v1alpha1.TiFlashConfig{
+					Config: &v1alpha1.CommonConfig{
+						Labels: labels,
+					},
+				},
+			}
 		}
 
-		created, err := am.cli.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(tc)
+		created, err := am.cli.PingcapV1alpha1().TidbClusters(childTc.Namespace).Create(childTc)
 		if err != nil {
 			klog.Errorf("cannot create new TidbCluster %v\n", err)
 			return err
@@ -198,8 +605,14 @@ func (am *autoScalerManager) createAutoscalingClusters(tc *v1alpha1.TidbCluster,
 			}
 			updated := monitor.DeepCopy()
 			updated.Spec.Clusters = append(updated.Spec.Clusters, v1alpha1.TidbClusterRef{Name: created.Name, Namespace: created.Namespace})
-			am.cli.PingcapV1alpha1().TidbMonitors(updated.Namespace).Update(updated)
+			_, err = am.cli.PingcapV1alpha1().TidbMonitors(updated.Namespace).Update(updated)
+			if err != nil {
+				return err
+			}
 		}
+
+		setCurrentResourceType(tacUpdate, group, plan.ResourceType)
 	}
+
 	return nil
 }
@@ -0,0 +1,124 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscaler
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestValidateAutoscalingComponent(t *testing.T) {
+	cases := []struct {
+		component string
+		wantErr   bool
+	}{
+		{string(v1alpha1.TiKVMemberType), false},
+		{string(v1alpha1.TiDBMemberType), false},
+		{string(v1alpha1.PDMemberType), false},
+		{string(v1alpha1.TiFlashMemberType), false},
+		{"ticdc", true},
+		{"", true},
+	}
+
+	for _, c := range cases {
+		err := validateAutoscalingComponent(c.component)
+		if c.wantErr && err == nil {
+			t.Errorf("component %q: expected an error, got nil", c.component)
+		}
+		if !c.wantErr && err != nil {
+			t.Errorf("component %q: unexpected error: %v", c.component, err)
+		}
+	}
+}
+
+func TestCreateAutoscalingClusters_BuildsSpecPerComponent(t *testing.T) {
+	tac := &v1alpha1.TidbClusterAutoScaler{
+		Spec: v1alpha1.TidbClusterAutoScalerSpec{
+			Resources: []v1alpha1.AutoResource{
+				{
+					ResourceType: "large",
+					CPU:          resource.MustParse("2"),
+					Memory:       resource.MustParse("4Gi"),
+					Storage:      resource.MustParse("100Gi"),
+				},
+			},
+		},
+	}
+	tac.Name = "tac"
+	tac.Namespace = "ns"
+	tc := &v1alpha1.TidbCluster{}
+	tc.Name = "tc"
+	tc.Namespace = "ns"
+
+	cases := []struct {
+		component string
+	}{
+		{string(v1alpha1.TiKVMemberType)},
+		{string(v1alpha1.TiDBMemberType)},
+		{string(v1alpha1.PDMemberType)},
+		{string(v1alpha1.TiFlashMemberType)},
+	}
+
+	for _, c := range cases {
+		cli := fake.NewSimpleClientset()
+		am := &autoScalerManager{cli: cli, recorder: record.NewFakeRecorder(10)}
+
+		group := "group-" + c.component
+		plan := pdapi.Plan{
+			Component:    c.component,
+			Count:        3,
+			ResourceType: "large",
+			Labels:       []pdapi.Label{{Key: label.AutoScalingGroupLabelKey, Value: group}},
+		}
+
+		err := am.createAutoscalingClusters(tc, tac.DeepCopy(), []string{group}, map[string]pdapi.Plan{group: plan})
+		if err != nil {
+			t.Fatalf("component %s: createAutoscalingClusters returned error: %v", c.component, err)
+		}
+
+		created, err := cli.PingcapV1alpha1().TidbClusters(tc.Namespace).Get(group, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("component %s: expected child TidbCluster to be created: %v", c.component, err)
+		}
+
+		switch c.component {
+		case string(v1alpha1.TiKVMemberType):
+			if created.Spec.TiKV == nil || created.Spec.TiKV.Replicas != 3 {
+				t.Errorf("component %s: expected TiKV.Replicas == 3, got %+v", c.component, created.Spec.TiKV)
+			}
+		case string(v1alpha1.TiDBMemberType):
+			if created.Spec.TiDB == nil || created.Spec.TiDB.Replicas != 3 {
+				t.Errorf("component %s: expected TiDB.Replicas == 3, got %+v", c.component, created.Spec.TiDB)
+			}
+		case string(v1alpha1.PDMemberType):
+			if created.Spec.PD == nil || created.Spec.PD.Replicas != 3 {
+				t.Errorf("component %s: expected PD.Replicas == 3, got %+v", c.component, created.Spec.PD)
+			}
+		case string(v1alpha1.TiFlashMemberType):
+			if created.Spec.TiFlash == nil || created.Spec.TiFlash.Replicas != 3 {
+				t.Errorf("component %s: expected TiFlash.Replicas == 3, got %+v", c.component, created.Spec.TiFlash)
+			}
+			if created.Spec.TiFlash.Config == nil || created.Spec.TiFlash.Config.Config == nil || created.Spec.TiFlash.Config.Config.Labels[label.AutoScalingGroupLabelKey] != group {
+				t.Errorf("component %s: expected store label %s=%s to be set", c.component, label.AutoScalingGroupLabelKey, group)
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestStepLimitedReplicas(t *testing.T) {
+	cases := []struct {
+		name                   string
+		current, desired, step int32
+		want                   int32
+	}{
+		{"scale out within step", 3, 4, 1, 4},
+		{"scale out beyond step is clamped", 3, 10, 2, 5},
+		{"scale in within step", 5, 4, 1, 4},
+		{"scale in beyond step is clamped", 10, 1, 2, 8},
+		{"no change", 3, 3, 1, 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := stepLimitedReplicas(c.current, c.desired, c.step)
+			if got != c.want {
+				t.Errorf("stepLimitedReplicas(%d, %d, %d) = %d, want %d", c.current, c.desired, c.step, got, c.want)
+			}
+		})
+	}
+}
+
+func TestClampToBounds(t *testing.T) {
+	minReplicas := int32(2)
+	basic := &v1alpha1.BasicAutoScalerSpec{MinReplicas: &minReplicas, MaxReplicas: 8}
+
+	cases := []struct {
+		name    string
+		basic   *v1alpha1.BasicAutoScalerSpec
+		desired int32
+		want    int32
+	}{
+		{"nil spec passes through", nil, 100, 100},
+		{"below min is raised", basic, 1, 2},
+		{"above max is lowered", basic, 20, 8},
+		{"within bounds is unchanged", basic, 5, 5},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := clampToBounds(c.basic, c.desired)
+			if got != c.want {
+				t.Errorf("clampToBounds(%v, %d) = %d, want %d", c.basic, c.desired, got, c.want)
+			}
+		})
+	}
+}
+
+func TestInCooldown(t *testing.T) {
+	scaleOutInterval := int32(300)
+	scaleInInterval := int32(600)
+	basic := &v1alpha1.BasicAutoScalerSpec{
+		ScaleOutIntervalSeconds: &scaleOutInterval,
+		ScaleInIntervalSeconds:  &scaleInInterval,
+	}
+
+	recentTac := &v1alpha1.TidbClusterAutoScaler{}
+	recentTac.Annotations = map[string]string{
+		lastScaleTimestampAnnotationPrefix + "g": time.Now().Add(-30 * time.Second).Format(time.RFC3339),
+	}
+	staleTac := &v1alpha1.TidbClusterAutoScaler{}
+	staleTac.Annotations = map[string]string{
+		lastScaleTimestampAnnotationPrefix + "g": time.Now().Add(-1 * time.Hour).Format(time.RFC3339),
+	}
+	neverScaledTac := &v1alpha1.TidbClusterAutoScaler{}
+
+	cases := []struct {
+		name     string
+		tac      *v1alpha1.TidbClusterAutoScaler
+		scaleOut bool
+		basic    *v1alpha1.BasicAutoScalerSpec
+		want     bool
+	}{
+		{"no basic spec never cools down", recentTac, true, nil, false},
+		{"never scaled before is not cooling down", neverScaledTac, true, basic, false},
+		{"recent scale-out is within cooldown", recentTac, true, basic, true},
+		{"recent scale-in is within the longer cooldown", recentTac, false, basic, true},
+		{"stale timestamp has left cooldown", staleTac, true, basic, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := inCooldown(c.tac, "g", c.scaleOut, c.basic)
+			if got != c.want {
+				t.Errorf("inCooldown(...) = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+type fakeTidbClusterControl struct {
+	controller.TidbClusterControlInterface
+	updated []*v1alpha1.TidbCluster
+}
+
+func (f *fakeTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster, newStatus, oldStatus *v1alpha1.TidbClusterStatus) (*v1alpha1.TidbCluster, error) {
+	f.updated = append(f.updated, tc)
+	return tc, nil
+}
+
+// TestSyncPlans_CreateAndUpdateShareSingleAnnotationUpdate exercises a sync
+// where one group is updated (writing a lastScaleTimestamp annotation) and
+// another group is created (writing a resourceType annotation) in the same
+// pass, and verifies both annotations land in a single persisted TAC update
+// instead of the create clobbering what the update just wrote.
+func TestSyncPlans_CreateAndUpdateShareSingleAnnotationUpdate(t *testing.T) {
+	tc := &v1alpha1.TidbCluster{}
+	tc.Name = "parent"
+	tc.Namespace = "ns"
+
+	existing := childTidbCluster("existing-group", "ns", "parent", "existing-group")
+	existing.Spec.TiKV = &v1alpha1.TiKVSpec{Replicas: 3}
+
+	tac := &v1alpha1.TidbClusterAutoScaler{}
+	tac.Name = "tac"
+	tac.Namespace = "ns"
+
+	cli := fake.NewSimpleClientset(tac, existing)
+	tcIndexer := newTidbClusterIndexer(existing)
+	tcControl := &fakeTidbClusterControl{}
+	am := &autoScalerManager{
+		cli:       cli,
+		tcLister:  listers.NewTidbClusterLister(tcIndexer),
+		tmLister:  listers.NewTidbMonitorLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+		tcControl: tcControl,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	plans := []pdapi.Plan{
+		{
+			Component:    string(v1alpha1.TiKVMemberType),
+			Count:        6,
+			ResourceType: "large",
+			Labels:       []pdapi.Label{{Key: label.AutoScalingGroupLabelKey, Value: "existing-group"}},
+		},
+		{
+			Component:    string(v1alpha1.TiDBMemberType),
+			Count:        2,
+			ResourceType: "large",
+			Labels:       []pdapi.Label{{Key: label.AutoScalingGroupLabelKey, Value: "new-group"}},
+		},
+	}
+
+	if err := am.syncPlans(tc, tac, plans); err != nil {
+		t.Fatalf("syncPlans returned error: %v", err)
+	}
+
+	persisted, err := cli.PingcapV1alpha1().TidbClusterAutoScalers("ns").Get("tac", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected tac to exist: %v", err)
+	}
+	if persisted.Annotations[lastScaleTimestampAnnotationPrefix+"existing-group"] == "" {
+		t.Errorf("expected lastScaleTimestamp annotation for existing-group to be persisted, got %+v", persisted.Annotations)
+	}
+	if persisted.Annotations[resourceTypeAnnotationPrefix+"new-group"] != "large" {
+		t.Errorf("expected resourceType annotation for new-group to be persisted, got %+v", persisted.Annotations)
+	}
+}
+
+// TestUpdateAutoscalingClusters_ResourceChangeStillStepLimitsReplicas exercises
+// a plan that changes the resource class *and* asks for a large replica jump
+// in the same sync. The resource/requests/limits swap should still be applied
+// immediately, but the replica count must remain step-limited rather than
+// being pushed straight to the plan's count just because the resource class
+// also changed.
+func TestUpdateAutoscalingClusters_ResourceChangeStillStepLimitsReplicas(t *testing.T) {
+	group := "group"
+	existing := childTidbCluster(group, "ns", "parent", group)
+	existing.Spec.TiKV = &v1alpha1.TiKVSpec{Replicas: 3}
+
+	tac := &v1alpha1.TidbClusterAutoScaler{}
+	tac.Name = "tac"
+	tac.Namespace = "ns"
+	tac.Annotations = map[string]string{
+		resourceTypeAnnotationPrefix + group: "small",
+		scaleStepSizeAnnotationKey:           "2",
+	}
+
+	tcControl := &fakeTidbClusterControl{}
+	am := &autoScalerManager{
+		cli:       fake.NewSimpleClientset(),
+		tcControl: tcControl,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	plan := pdapi.Plan{
+		Component:    string(v1alpha1.TiKVMemberType),
+		Count:        50,
+		ResourceType: "large",
+		Labels:       []pdapi.Label{{Key: label.AutoScalingGroupLabelKey, Value: group}},
+	}
+
+	groupTcMap := map[string]*v1alpha1.TidbCluster{group: existing}
+	groupPlanMap := map[string]pdapi.Plan{group: plan}
+
+	if err := am.updateAutoscalingClusters(tac, []string{group}, groupTcMap, groupPlanMap); err != nil {
+		t.Fatalf("updateAutoscalingClusters returned error: %v", err)
+	}
+
+	if len(tcControl.updated) != 1 {
+		t.Fatalf("expected exactly one TidbCluster update, got %d", len(tcControl.updated))
+	}
+	updated := tcControl.updated[0]
+	if updated.Spec.TiKV.Replicas != 5 {
+		t.Errorf("expected replicas to be step-limited to 5 (current 3 + step 2), got %d", updated.Spec.TiKV.Replicas)
+	}
+	if tac.Annotations[resourceTypeAnnotationPrefix+group] != "large" {
+		t.Errorf("expected resource type annotation to be updated to the new class immediately, got %+v", tac.Annotations)
+	}
+}
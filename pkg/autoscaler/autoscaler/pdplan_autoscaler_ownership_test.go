@@ -0,0 +1,110 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autoscaler
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func newTidbClusterIndexer(tcs ...*v1alpha1.TidbCluster) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, tc := range tcs {
+		indexer.Add(tc)
+	}
+	return indexer
+}
+
+func childTidbCluster(name, namespace, parentName, group string) *v1alpha1.TidbCluster {
+	child := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{label.AutoScalingGroupLabelKey: group},
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			Cluster: &v1alpha1.TidbClusterRef{Name: parentName, Namespace: namespace},
+		},
+	}
+	return child
+}
+
+func TestReconcileOrphanedClusters_AdoptsAndGarbageCollects(t *testing.T) {
+	tc := &v1alpha1.TidbCluster{}
+	tc.Name = "parent"
+	tc.Namespace = "ns"
+	tac := &v1alpha1.TidbClusterAutoScaler{}
+	tac.Name = "tac"
+	tac.Namespace = "ns"
+	tac.UID = "tac-uid"
+
+	// "kept" is still part of the current plan but is missing its owner
+	// reference, e.g. because it was created by an older operator version.
+	kept := childTidbCluster("kept", "ns", "parent", "kept")
+	// "stale" is no longer part of the current plan set at all, and since it
+	// isn't in groupNames it would never be looked at by the `In` selector
+	// the rest of syncPlans uses.
+	stale := childTidbCluster("stale", "ns", "parent", "stale")
+
+	cli := fake.NewSimpleClientset(kept, stale)
+	indexer := newTidbClusterIndexer(kept, stale)
+	am := &autoScalerManager{
+		cli:      cli,
+		tcLister: listers.NewTidbClusterLister(indexer),
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	if err := am.reconcileOrphanedClusters(tc, tac, sets.NewString("kept")); err != nil {
+		t.Fatalf("reconcileOrphanedClusters returned error: %v", err)
+	}
+
+	adopted, err := cli.PingcapV1alpha1().TidbClusters("ns").Get("kept", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected kept TidbCluster to still exist: %v", err)
+	}
+	if ref := metav1.GetControllerOf(adopted); ref == nil || ref.Name != tac.Name || ref.UID != tac.UID {
+		t.Errorf("expected kept TidbCluster to be adopted by tac, got owner refs %+v", adopted.OwnerReferences)
+	}
+
+	if _, err := cli.PingcapV1alpha1().TidbClusters("ns").Get("stale", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected stale TidbCluster to be garbage-collected")
+	}
+}
+
+func TestDeleteAutoscalingClusters_ToleratesNotFound(t *testing.T) {
+	tc := &v1alpha1.TidbCluster{}
+	tc.Name = "parent"
+	tc.Namespace = "ns"
+
+	// deleteTc is handed to deleteAutoscalingClusters via groupTcMap but was
+	// never actually created in the fake clientset, simulating state left
+	// over from a previous, partially-applied delete.
+	deleteTc := childTidbCluster("gone", "ns", "parent", "gone")
+
+	cli := fake.NewSimpleClientset()
+	am := &autoScalerManager{cli: cli, recorder: record.NewFakeRecorder(10)}
+
+	err := am.deleteAutoscalingClusters(tc, []string{"gone"}, map[string]*v1alpha1.TidbCluster{"gone": deleteTc})
+	if err != nil {
+		t.Fatalf("expected deleteAutoscalingClusters to tolerate a NotFound delete, got: %v", err)
+	}
+}